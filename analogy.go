@@ -0,0 +1,181 @@
+package word2vec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+)
+
+// AnalogyQuery solves the classic analogy "A is to B as C is to ?" by
+// evaluating B - A + C and returning the N nearest matches, excluding A, B,
+// and C themselves.
+type AnalogyQuery struct {
+	A string `json:"a"`
+	B string `json:"b"`
+	C string `json:"c"`
+	N int    `json:"n"`
+}
+
+type AnalogyResponse struct {
+	Matches []Match `json:"matches"`
+}
+
+func (q AnalogyQuery) Eval(m *Model) (*AnalogyResponse, error) {
+	expr := Expr{}
+	expr.Add(-1, q.A)
+	expr.Add(1, q.B)
+	expr.Add(1, q.C)
+
+	matches, err := ExprMostSim(m, q.N, expr)
+	if err != nil {
+		return nil, err
+	}
+	return &AnalogyResponse{Matches: matches}, nil
+}
+
+// ExprMostSimQuery is the wire request for the generic vector-arithmetic
+// endpoint: like MostSimQuery, but it accepts several Exprs, merged and
+// filtered the way ExprMostSim does, instead of a single one whose seed
+// words are eligible to appear in the results.
+type ExprMostSimQuery struct {
+	Exprs []Expr `json:"exprs"`
+	N     int    `json:"n"`
+}
+
+type ExprMostSimResponse struct {
+	Matches []Match `json:"matches"`
+}
+
+func (q ExprMostSimQuery) Eval(m *Model) (*ExprMostSimResponse, error) {
+	matches, err := ExprMostSim(m, q.N, q.Exprs...)
+	if err != nil {
+		return nil, err
+	}
+	return &ExprMostSimResponse{Matches: matches}, nil
+}
+
+// ExprMostSim merges exprs into a single set of per-word coefficients
+// (summing coefficients for a word that appears in more than one), combines
+// their L2-normalized word vectors accordingly, and returns the N nearest
+// matches with every word that appeared in any input expr excluded from the
+// results. AnalogyQuery and ExprMostSimQuery both build on this: a plain
+// MostSimQuery always leaves its own seed words eligible to come back as
+// their own nearest match, which is almost never what an analogy-style
+// caller wants.
+func ExprMostSim(m *Model, n int, exprs ...Expr) ([]Match, error) {
+	if len(exprs) == 0 {
+		return nil, fmt.Errorf("must specify at least one expression")
+	}
+
+	coeffs := Expr{}
+	for _, e := range exprs {
+		for w, f := range e {
+			coeffs.Add(f, w)
+		}
+	}
+
+	// Normalize each word's vector to unit length before scaling and
+	// summing it in, so "king - man + woman" isn't skewed by whichever of
+	// the three words happens to have the largest raw vector norm - the
+	// same reason the classic word2vec analogy task works in normalized
+	// vector space rather than raw embeddings.
+	var v Vector
+	seeds := make(map[string]bool, len(coeffs))
+	for w, f := range coeffs {
+		wv, err := Expr{w: 1}.Eval(m)
+		if err != nil {
+			return nil, err
+		}
+		v = addScaled(v, normalize(wv), f)
+		seeds[w] = true
+	}
+
+	// Over-fetch so filtering out the seed words still leaves n matches.
+	matches := m.MostSimilar(v, n+len(seeds))
+	result := make([]Match, 0, n)
+	for _, match := range matches {
+		if seeds[match.Word] {
+			continue
+		}
+		result = append(result, match)
+		if len(result) == n {
+			break
+		}
+	}
+	return result, nil
+}
+
+// normalize scales v to unit L2 length, or returns it unchanged if it's the
+// zero vector.
+func normalize(v Vector) Vector {
+	var sumSq float64
+	for _, x := range v {
+		sumSq += float64(x) * float64(x)
+	}
+	if sumSq == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sumSq))
+	out := make(Vector, len(v))
+	for i, x := range v {
+		out[i] = x / norm
+	}
+	return out
+}
+
+// addScaled adds src*scale into dst elementwise, allocating dst from src's
+// length if it's nil.
+func addScaled(dst, src Vector, scale float32) Vector {
+	if dst == nil {
+		dst = make(Vector, len(src))
+	}
+	for i, x := range src {
+		dst[i] += x * scale
+	}
+	return dst
+}
+
+func (m *ModelServer) HandleAnalogyQuery(w http.ResponseWriter, r *http.Request) {
+	serve[AnalogyQuery, AnalogyResponse](m, w, r, AnalogyQuery.Eval)
+}
+
+func (m *ModelServer) HandleExprMostSimQuery(w http.ResponseWriter, r *http.Request) {
+	serve[ExprMostSimQuery, ExprMostSimResponse](m, w, r, ExprMostSimQuery.Eval)
+}
+
+// Analogy solves "a is to b as c is to ?", mirroring ModelServer's /analogy
+// endpoint.
+func (c Client) Analogy(ctx context.Context, a, b, cWord string, n int) ([]Match, error) {
+	req := AnalogyQuery{A: a, B: b, C: cWord, N: n}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var data AnalogyResponse
+	if err := c.do(ctx, "/analogy", body, &data); err != nil {
+		return nil, err
+	}
+	return data.Matches, nil
+}
+
+// ExprMostSim mirrors ModelServer's /expr-most-sim endpoint: it merges
+// exprs and returns the n nearest matches, excluding every word that
+// appears in any of them.
+func (c Client) ExprMostSim(ctx context.Context, n int, exprs ...Expr) ([]Match, error) {
+	req := ExprMostSimQuery{Exprs: exprs, N: n}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var data ExprMostSimResponse
+	if err := c.do(ctx, "/expr-most-sim", body, &data); err != nil {
+		return nil, err
+	}
+	return data.Matches, nil
+}
@@ -0,0 +1,241 @@
+package word2vec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
+	proto "github.com/golang/protobuf/proto"
+
+	"github.com/idcooldi/word2vec/word2vecpb"
+)
+
+// Codec marshals and unmarshals the query/response types exchanged over
+// HTTP, letting ModelServer and Client support encodings beyond JSON
+// without the handlers or callers knowing about any of them directly.
+// Register additional formats (msgpack, CBOR, ...) with
+// ModelServer.RegisterCodec; they're selected by Content-Type/Accept the
+// same way the built-in codecs are.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	ContentType() string
+}
+
+// jsonCodec is the fallback used when no codec matches the request's
+// Content-Type or Accept header.
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string                        { return "application/json" }
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// gobCodec encodes with encoding/gob, which works directly against the
+// plain SimQuery/MostSimResponse/etc. structs without any conversion.
+type gobCodec struct{}
+
+func (gobCodec) ContentType() string { return "application/gob" }
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// protobufCodec encodes via the word2vecpb messages, converting to and from
+// the plain SimQuery/MostSimResponse/etc. structs that the rest of the
+// package works with. It cuts both payload size and decode CPU well below
+// JSON for MostSimResponse, whose Match slices dominate the wire format.
+type protobufCodec struct{}
+
+func (protobufCodec) ContentType() string { return "application/x-protobuf" }
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	m, err := toProtoMessage(v)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(m)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	return fromProtoMessage(data, v)
+}
+
+func toProtoMessage(v interface{}) (proto.Message, error) {
+	switch q := v.(type) {
+	case *SimQuery:
+		return &word2vecpb.SimQuery{A: exprToPB(q.A), B: exprToPB(q.B)}, nil
+	case *SimResponse:
+		return &word2vecpb.SimResponse{Value: q.Value}, nil
+	case *MultiSimQuery:
+		queries := make([]*word2vecpb.SimQuery, len(q.Queries))
+		for i, sq := range q.Queries {
+			queries[i] = &word2vecpb.SimQuery{A: exprToPB(sq.A), B: exprToPB(sq.B)}
+		}
+		return &word2vecpb.MultiSimQuery{Queries: queries}, nil
+	case *MultiSimResponse:
+		values := make([]*word2vecpb.SimResponse, len(q.Values))
+		for i, v := range q.Values {
+			values[i] = &word2vecpb.SimResponse{Value: v.Value}
+		}
+		return &word2vecpb.MultiSimResponse{Values: values}, nil
+	case *MostSimQuery:
+		return &word2vecpb.MostSimQuery{Expr: exprToPB(q.Expr), N: int32(q.N)}, nil
+	case *MostSimResponse:
+		matches := make([]*word2vecpb.Match, len(q.Matches))
+		for i, m := range q.Matches {
+			matches[i] = matchToPB(m)
+		}
+		return &word2vecpb.MostSimResponse{Matches: matches}, nil
+	case *AnalogyQuery:
+		return &word2vecpb.AnalogyQuery{A: q.A, B: q.B, C: q.C, N: int32(q.N)}, nil
+	case *AnalogyResponse:
+		matches := make([]*word2vecpb.Match, len(q.Matches))
+		for i, m := range q.Matches {
+			matches[i] = matchToPB(m)
+		}
+		return &word2vecpb.AnalogyResponse{Matches: matches}, nil
+	case *ExprMostSimQuery:
+		exprs := make([]*word2vecpb.Expr, len(q.Exprs))
+		for i, e := range q.Exprs {
+			exprs[i] = exprToPB(e)
+		}
+		return &word2vecpb.ExprMostSimQuery{Exprs: exprs, N: int32(q.N)}, nil
+	case *ExprMostSimResponse:
+		matches := make([]*word2vecpb.Match, len(q.Matches))
+		for i, m := range q.Matches {
+			matches[i] = matchToPB(m)
+		}
+		return &word2vecpb.ExprMostSimResponse{Matches: matches}, nil
+	default:
+		return nil, fmt.Errorf("word2vec: no protobuf mapping for %T", v)
+	}
+}
+
+func fromProtoMessage(data []byte, v interface{}) error {
+	switch q := v.(type) {
+	case *SimQuery:
+		var pb word2vecpb.SimQuery
+		if err := proto.Unmarshal(data, &pb); err != nil {
+			return err
+		}
+		*q = SimQuery{A: exprFromPB(pb.GetA()), B: exprFromPB(pb.GetB())}
+		return nil
+	case *SimResponse:
+		var pb word2vecpb.SimResponse
+		if err := proto.Unmarshal(data, &pb); err != nil {
+			return err
+		}
+		*q = SimResponse{Value: pb.GetValue()}
+		return nil
+	case *MultiSimQuery:
+		var pb word2vecpb.MultiSimQuery
+		if err := proto.Unmarshal(data, &pb); err != nil {
+			return err
+		}
+		queries := make([]SimQuery, len(pb.GetQueries()))
+		for i, sq := range pb.GetQueries() {
+			queries[i] = SimQuery{A: exprFromPB(sq.GetA()), B: exprFromPB(sq.GetB())}
+		}
+		*q = MultiSimQuery{Queries: queries}
+		return nil
+	case *MultiSimResponse:
+		var pb word2vecpb.MultiSimResponse
+		if err := proto.Unmarshal(data, &pb); err != nil {
+			return err
+		}
+		values := make([]SimResponse, len(pb.GetValues()))
+		for i, v := range pb.GetValues() {
+			values[i] = SimResponse{Value: v.GetValue()}
+		}
+		*q = MultiSimResponse{Values: values}
+		return nil
+	case *MostSimQuery:
+		var pb word2vecpb.MostSimQuery
+		if err := proto.Unmarshal(data, &pb); err != nil {
+			return err
+		}
+		*q = MostSimQuery{Expr: exprFromPB(pb.GetExpr()), N: int(pb.GetN())}
+		return nil
+	case *MostSimResponse:
+		var pb word2vecpb.MostSimResponse
+		if err := proto.Unmarshal(data, &pb); err != nil {
+			return err
+		}
+		matches := make([]Match, len(pb.GetMatches()))
+		for i, m := range pb.GetMatches() {
+			matches[i] = matchFromPB(m)
+		}
+		*q = MostSimResponse{Matches: matches}
+		return nil
+	case *AnalogyQuery:
+		var pb word2vecpb.AnalogyQuery
+		if err := proto.Unmarshal(data, &pb); err != nil {
+			return err
+		}
+		*q = AnalogyQuery{A: pb.GetA(), B: pb.GetB(), C: pb.GetC(), N: int(pb.GetN())}
+		return nil
+	case *AnalogyResponse:
+		var pb word2vecpb.AnalogyResponse
+		if err := proto.Unmarshal(data, &pb); err != nil {
+			return err
+		}
+		matches := make([]Match, len(pb.GetMatches()))
+		for i, m := range pb.GetMatches() {
+			matches[i] = matchFromPB(m)
+		}
+		*q = AnalogyResponse{Matches: matches}
+		return nil
+	case *ExprMostSimQuery:
+		var pb word2vecpb.ExprMostSimQuery
+		if err := proto.Unmarshal(data, &pb); err != nil {
+			return err
+		}
+		exprs := make([]Expr, len(pb.GetExprs()))
+		for i, e := range pb.GetExprs() {
+			exprs[i] = exprFromPB(e)
+		}
+		*q = ExprMostSimQuery{Exprs: exprs, N: int(pb.GetN())}
+		return nil
+	case *ExprMostSimResponse:
+		var pb word2vecpb.ExprMostSimResponse
+		if err := proto.Unmarshal(data, &pb); err != nil {
+			return err
+		}
+		matches := make([]Match, len(pb.GetMatches()))
+		for i, m := range pb.GetMatches() {
+			matches[i] = matchFromPB(m)
+		}
+		*q = ExprMostSimResponse{Matches: matches}
+		return nil
+	default:
+		return fmt.Errorf("word2vec: no protobuf mapping for %T", v)
+	}
+}
+
+// defaultCodecs are always available on a ModelServer, even one with a nil
+// Codecs slice; Codecs registered via RegisterCodec take precedence, so a
+// caller can override application/x-protobuf or application/gob if needed.
+var defaultCodecs = []Codec{protobufCodec{}, gobCodec{}}
+
+func codecByContentType(codecs []Codec, contentType string) Codec {
+	for _, c := range codecs {
+		if c.ContentType() == contentType {
+			return c
+		}
+	}
+	for _, c := range defaultCodecs {
+		if c.ContentType() == contentType {
+			return c
+		}
+	}
+	return nil
+}
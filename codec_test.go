@@ -0,0 +1,64 @@
+package word2vec
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestCodecRoundTrip checks that every built-in Codec can marshal and
+// unmarshal each wire type back to an equal value.
+func TestCodecRoundTrip(t *testing.T) {
+	values := []interface{}{
+		&SimQuery{A: Expr{"a": 1}, B: Expr{"b": -1}},
+		&SimResponse{Value: 0.25},
+		&MultiSimQuery{Queries: []SimQuery{{A: Expr{"a": 1}, B: Expr{"b": 1}}}},
+		&MultiSimResponse{Values: []SimResponse{{Value: 0.5}}},
+		&MostSimQuery{Expr: Expr{"a": 1}, N: 5},
+		&MostSimResponse{Matches: []Match{{Word: "queen", Score: 0.9}}},
+		&AnalogyQuery{A: "man", B: "king", C: "woman", N: 1},
+		&AnalogyResponse{Matches: []Match{{Word: "queen", Score: 0.9}}},
+		&ExprMostSimQuery{Exprs: []Expr{{"a": 1}, {"b": -1}}, N: 3},
+		&ExprMostSimResponse{Matches: []Match{{Word: "queen", Score: 0.9}}},
+	}
+
+	codecs := []Codec{jsonCodec{}, protobufCodec{}, gobCodec{}}
+
+	for _, codec := range codecs {
+		for _, v := range values {
+			b, err := codec.Marshal(v)
+			if err != nil {
+				t.Fatalf("%s: Marshal(%#v): %v", codec.ContentType(), v, err)
+			}
+
+			out := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+			if err := codec.Unmarshal(b, out); err != nil {
+				t.Fatalf("%s: Unmarshal(%#v): %v", codec.ContentType(), v, err)
+			}
+
+			if !reflect.DeepEqual(v, out) {
+				t.Fatalf("%s: round trip of %#v produced %#v", codec.ContentType(), v, out)
+			}
+		}
+	}
+}
+
+// TestCodecByContentType checks the lookup ModelServer and Client both use
+// to pick a Codec from a Content-Type/Accept header, including the
+// always-available defaults and the "no match" case.
+func TestCodecByContentType(t *testing.T) {
+	if c := codecByContentType(nil, "application/x-protobuf"); c == nil {
+		t.Fatal("expected application/x-protobuf to resolve to the default protobufCodec")
+	}
+	if c := codecByContentType(nil, "application/gob"); c == nil {
+		t.Fatal("expected application/gob to resolve to the default gobCodec")
+	}
+	if c := codecByContentType(nil, "application/does-not-exist"); c != nil {
+		t.Fatalf("expected no codec for an unregistered content type, got %T", c)
+	}
+
+	custom := jsonCodec{}
+	codecs := []Codec{custom}
+	if c := codecByContentType(codecs, "application/json"); c == nil {
+		t.Fatal("expected a registered codec to be found by its content type")
+	}
+}
@@ -0,0 +1,166 @@
+package word2vec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/idcooldi/word2vec/word2vecpb"
+)
+
+// grpcError maps a query error to the gRPC status it should be reported as.
+// ErrUnknownWord becomes codes.NotFound so callers can distinguish "the
+// model doesn't have this word" from other failures; everything else passes
+// through unchanged (and picks up gRPC's default codes.Unknown).
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ErrUnknownWord) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return err
+}
+
+// GRPCModelServer adapts a Model to the word2vecpb.ModelServiceServer
+// interface, exposing the same operations as ModelServer over gRPC instead
+// of HTTP+JSON.
+type GRPCModelServer struct {
+	*Model
+}
+
+func exprFromPB(e *word2vecpb.Expr) Expr {
+	expr := make(Expr, len(e.GetTerms()))
+	for w, f := range e.GetTerms() {
+		expr[w] = f
+	}
+	return expr
+}
+
+func exprToPB(e Expr) *word2vecpb.Expr {
+	terms := make(map[string]float32, len(e))
+	for w, f := range e {
+		terms[w] = f
+	}
+	return &word2vecpb.Expr{Terms: terms}
+}
+
+func matchToPB(m Match) *word2vecpb.Match {
+	return &word2vecpb.Match{Word: m.Word, Score: m.Score}
+}
+
+func matchFromPB(m *word2vecpb.Match) Match {
+	return Match{Word: m.GetWord(), Score: m.GetScore()}
+}
+
+// Sim implements word2vecpb.ModelServiceServer.
+func (s *GRPCModelServer) Sim(ctx context.Context, q *word2vecpb.SimQuery) (*word2vecpb.SimResponse, error) {
+	sq := SimQuery{A: exprFromPB(q.GetA()), B: exprFromPB(q.GetB())}
+	resp, err := sq.Eval(s.Model)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &word2vecpb.SimResponse{Value: resp.Value}, nil
+}
+
+// MultiSim implements word2vecpb.ModelServiceServer.
+func (s *GRPCModelServer) MultiSim(ctx context.Context, q *word2vecpb.MultiSimQuery) (*word2vecpb.MultiSimResponse, error) {
+	mq := MultiSimQuery{Queries: make([]SimQuery, len(q.GetQueries()))}
+	for i, sq := range q.GetQueries() {
+		mq.Queries[i] = SimQuery{A: exprFromPB(sq.GetA()), B: exprFromPB(sq.GetB())}
+	}
+	resp, err := mq.Eval(s.Model)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	values := make([]*word2vecpb.SimResponse, len(resp.Values))
+	for i, v := range resp.Values {
+		values[i] = &word2vecpb.SimResponse{Value: v.Value}
+	}
+	return &word2vecpb.MultiSimResponse{Values: values}, nil
+}
+
+// MostSimilar implements word2vecpb.ModelServiceServer, streaming matches to
+// the client as soon as the full top-N has been computed.
+func (s *GRPCModelServer) MostSimilar(q *word2vecpb.MostSimQuery, stream word2vecpb.ModelService_MostSimilarServer) error {
+	mq := MostSimQuery{Expr: exprFromPB(q.GetExpr()), N: int(q.GetN())}
+	resp, err := mq.Eval(s.Model)
+	if err != nil {
+		return grpcError(err)
+	}
+	for _, m := range resp.Matches {
+		if err := stream.Send(matchToPB(m)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GRPCClient is the gRPC counterpart of Client: it implements the same
+// Sim/MultiSim/MostSim operations, backed by a *grpc.ClientConn instead of
+// an *http.Client.
+type GRPCClient struct {
+	conn   *grpc.ClientConn
+	client word2vecpb.ModelServiceClient
+}
+
+// NewGRPCClient wraps an already-dialed *grpc.ClientConn.
+func NewGRPCClient(conn *grpc.ClientConn) *GRPCClient {
+	return &GRPCClient{
+		conn:   conn,
+		client: word2vecpb.NewModelServiceClient(conn),
+	}
+}
+
+func (c *GRPCClient) Sim(ctx context.Context, x, y Expr) (float32, error) {
+	resp, err := c.client.Sim(ctx, &word2vecpb.SimQuery{A: exprToPB(x), B: exprToPB(y)})
+	if err != nil {
+		return 0, err
+	}
+	return resp.GetValue(), nil
+}
+
+func (c *GRPCClient) MultiSim(ctx context.Context, pairs [][2]Expr) ([]float32, error) {
+	req := &word2vecpb.MultiSimQuery{Queries: make([]*word2vecpb.SimQuery, len(pairs))}
+	for i, pair := range pairs {
+		req.Queries[i] = &word2vecpb.SimQuery{A: exprToPB(pair[0]), B: exprToPB(pair[1])}
+	}
+
+	resp, err := c.client.MultiSim(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]float32, len(resp.GetValues()))
+	for i, v := range resp.GetValues() {
+		result[i] = v.GetValue()
+	}
+	return result, nil
+}
+
+// MostSim calls the MostSimilar RPC and collects the full stream of matches,
+// mirroring the shape of Client's MostSim.
+func (c *GRPCClient) MostSim(ctx context.Context, expr Expr, n int) ([]Match, error) {
+	stream, err := c.client.MostSimilar(ctx, &word2vecpb.MostSimQuery{Expr: exprToPB(expr), N: int32(n)})
+	if err != nil {
+		return nil, fmt.Errorf("error calling MostSimilar: %v", err)
+	}
+
+	var matches []Match
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error receiving match: %v", err)
+		}
+		matches = append(matches, matchFromPB(m))
+	}
+	return matches, nil
+}
@@ -2,11 +2,17 @@ package word2vec
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math/rand"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Expr is a type which represents a linear expresssion which can be evaluated to a vector
@@ -35,6 +41,13 @@ func (e Expr) Eval(m *Model) (Vector, error) {
 	return m.Evaluate(e)
 }
 
+// ErrUnknownWord is the error Model.Evaluate returns, wrapped or unwrapped,
+// when an Expr names a word outside the model's vocabulary. Callers that
+// need to distinguish "bad input" from "this word just isn't in the model"
+// should check for it with errors.Is; GRPCModelServer does this to report
+// codes.NotFound instead of a generic failure.
+var ErrUnknownWord = errors.New("word2vec: unknown word")
+
 type SimQuery struct {
 	A Expr `json:"a,omitempty"`
 	B Expr `json:"b,omitempty"`
@@ -102,6 +115,44 @@ func (q MostSimQuery) Eval(m *Model) (*MostSimResponse, error) {
 
 type ModelServer struct {
 	*Model
+
+	// Codecs are additional wire formats this server accepts and can reply
+	// with, selected by the request's Content-Type (decoding) and Accept
+	// (encoding) headers. application/x-protobuf and application/gob are
+	// always available even with a nil Codecs; register here to add
+	// msgpack, CBOR, etc., or to override a built-in codec. JSON remains
+	// the fallback when nothing else matches.
+	Codecs []Codec
+}
+
+// RegisterCodec adds a Codec to the server.
+func (m *ModelServer) RegisterCodec(c Codec) {
+	m.Codecs = append(m.Codecs, c)
+}
+
+// requestCodec picks the codec matching the request's Content-Type header,
+// defaulting to JSON.
+func (m *ModelServer) requestCodec(r *http.Request) Codec {
+	ct := strings.TrimSpace(strings.SplitN(r.Header.Get("Content-Type"), ";", 2)[0])
+	if c := codecByContentType(m.Codecs, ct); c != nil {
+		return c
+	}
+	return jsonCodec{}
+}
+
+// responseCodec picks a response encoding from the request's Accept header,
+// preferring each comma-separated value in order, and defaulting to JSON.
+func (m *ModelServer) responseCodec(r *http.Request) Codec {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		ct := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if ct == "" || ct == "*/*" {
+			continue
+		}
+		if c := codecByContentType(m.Codecs, ct); c != nil {
+			return c
+		}
+	}
+	return jsonCodec{}
 }
 
 func handleError(w http.ResponseWriter, r *http.Request, status int, msg string) {
@@ -111,152 +162,372 @@ func handleError(w http.ResponseWriter, r *http.Request, status int, msg string)
 	return
 }
 
-func (m *ModelServer) HandleSimQuery(w http.ResponseWriter, r *http.Request) {
-	dec := json.NewDecoder(r.Body)
+// evalWithCancel runs eval(q, m) on its own goroutine and returns its
+// result, or ctx's error if ctx is done first. eval takes no context, so a
+// canceled ctx only stops the caller from waiting on a response nobody
+// wants; it does not stop eval itself, which keeps running on the goroutine
+// to completion.
+func evalWithCancel[Q, R any](ctx context.Context, m *Model, q Q, eval func(Q, *Model) (*R, error)) (*R, error) {
+	type result struct {
+		resp *R
+		err  error
+	}
+	doneCh := make(chan result, 1)
+	go func() {
+		resp, err := eval(q, m)
+		doneCh <- result{resp, err}
+	}()
+
+	select {
+	case res := <-doneCh:
+		return res.resp, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// serve implements the request/response lifecycle shared by every
+// ModelServer handler: decode the body into a Q with the request's codec,
+// run eval via evalWithCancel, and encode whatever it returns with the
+// negotiated response codec. eval is usually a query type's own Eval method
+// expression, e.g. serve[SimQuery, SimResponse](m, w, r, SimQuery.Eval).
+func serve[Q, R any](m *ModelServer, w http.ResponseWriter, r *http.Request, eval func(Q, *Model) (*R, error)) {
 	defer r.Body.Close()
 
-	var q SimQuery
-	err := dec.Decode(&q)
+	reqBody, err := ioutil.ReadAll(r.Body)
 	if err != nil {
+		msg := fmt.Sprintf("error reading request: %v", err)
+		handleError(w, r, http.StatusInternalServerError, msg)
+		return
+	}
+
+	var q Q
+	if err := m.requestCodec(r).Unmarshal(reqBody, &q); err != nil {
 		msg := fmt.Sprintf("error decoding query: %v", err)
 		handleError(w, r, http.StatusInternalServerError, msg)
 		return
 	}
 
-	resp, err := q.Eval(m.Model)
+	resp, err := evalWithCancel(r.Context(), m.Model, q, eval)
 	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			msg := fmt.Sprintf("request canceled: %v", err)
+			handleError(w, r, http.StatusGatewayTimeout, msg)
+			return
+		}
 		msg := fmt.Sprintf("error evaluating query: %v", err)
 		handleError(w, r, http.StatusBadRequest, msg)
 		return
 	}
 
-	b, err := json.Marshal(resp)
+	respCodec := m.responseCodec(r)
+	b, err := respCodec.Marshal(resp)
 	if err != nil {
-		msg := fmt.Sprintf("error encoding response %#v to JSON: %v", resp, err)
+		msg := fmt.Sprintf("error encoding response %#v: %v", resp, err)
 		handleError(w, r, http.StatusInternalServerError, msg)
 		return
 	}
 
-	_, err = w.Write(b)
-	if err != nil {
+	w.Header().Set("Content-Type", respCodec.ContentType())
+	if _, err := w.Write(b); err != nil {
 		log.Printf("error writing response: %v", err)
 	}
 }
 
+func (m *ModelServer) HandleSimQuery(w http.ResponseWriter, r *http.Request) {
+	serve[SimQuery, SimResponse](m, w, r, SimQuery.Eval)
+}
+
 func (m *ModelServer) HandleMultiSimQuery(w http.ResponseWriter, r *http.Request) {
-	dec := json.NewDecoder(r.Body)
-	defer r.Body.Close()
+	serve[MultiSimQuery, MultiSimResponse](m, w, r, MultiSimQuery.Eval)
+}
 
-	var q MultiSimQuery
-	err := dec.Decode(&q)
-	if err != nil {
-		msg := fmt.Sprintf("error decoding query: %v", err)
-		handleError(w, r, http.StatusInternalServerError, msg)
-		return
-	}
+func (m *ModelServer) HandleMostSimQuery(w http.ResponseWriter, r *http.Request) {
+	serve[MostSimQuery, MostSimResponse](m, w, r, MostSimQuery.Eval)
+}
 
-	resp, err := q.Eval(m.Model)
-	if err != nil {
-		msg := fmt.Sprintf("error evaluating query: %v", err)
-		handleError(w, r, http.StatusBadRequest, msg)
-		return
-	}
+type Client struct {
+	Addr string
 
-	b, err := json.Marshal(resp)
-	if err != nil {
-		msg := fmt.Sprintf("error encoding response %#v to JSON: %v", resp, err)
-		handleError(w, r, http.StatusInternalServerError, msg)
-		return
-	}
+	// Timeout bounds the overall call, from request construction through
+	// reading the response body. Zero means no default; callers relying on
+	// a bare context.Background() will block until the server answers.
+	Timeout time.Duration
+
+	// WriteTimeout and ReadTimeout bound, independently of Timeout, the
+	// time to send the request and the time to read the response,
+	// mirroring the split read/write deadlines on a net.Conn. Zero means
+	// no separate bound for that direction.
+	WriteTimeout time.Duration
+	ReadTimeout  time.Duration
+
+	// HTTPClient is used to issue requests. It defaults to a package-level
+	// client tuned for many concurrent calls against a single host; set it
+	// directly or via SetHTTPClient to inject your own, e.g. one already
+	// configured with tracing or custom TLS settings.
+	HTTPClient *http.Client
+
+	// Retry controls retries of failed requests. A zero RetryPolicy
+	// disables retries.
+	Retry RetryPolicy
+}
 
-	_, err = w.Write(b)
-	if err != nil {
-		log.Printf("error writing response: %v", err)
+// SetHTTPClient overrides the http.Client used for requests. Passing nil
+// restores the package default.
+func (c *Client) SetHTTPClient(hc *http.Client) {
+	c.HTTPClient = hc
+}
+
+func (c Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
 	}
+	return defaultHTTPClient
 }
 
-func (m *ModelServer) HandleMostSimQuery(w http.ResponseWriter, r *http.Request) {
-	dec := json.NewDecoder(r.Body)
-	defer r.Body.Close()
+// defaultTransport is tuned for a client issuing many concurrent Sim calls
+// against a single search service: keep-alives stay on, and the per-host
+// idle pool is large enough that connections are reused rather than
+// re-established for every call.
+var defaultTransport = &http.Transport{
+	Proxy:               http.ProxyFromEnvironment,
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 64,
+	IdleConnTimeout:     90 * time.Second,
+}
 
-	var q MostSimQuery
-	err := dec.Decode(&q)
-	if err != nil {
-		msg := fmt.Sprintf("error decoding query: %v", err)
-		handleError(w, r, http.StatusInternalServerError, msg)
-		return
-	}
+var defaultHTTPClient = &http.Client{
+	Transport: defaultTransport,
+}
 
-	resp, err := q.Eval(m.Model)
-	if err != nil {
-		msg := fmt.Sprintf("error evaluating query: %v", err)
-		handleError(w, r, http.StatusBadRequest, msg)
-		return
-	}
+// RetryPolicy controls how Client retries a request that fails with a
+// connection error or an idempotent 5xx response. A zero value disables
+// retries (MaxAttempts <= 1).
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values <= 1 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry; each subsequent
+	// retry doubles it.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the computed backoff, applied after jitter.
+	MaxBackoff time.Duration
+
+	// Jitter is the fraction, in [0, 1], of the computed backoff to
+	// randomize, so that many clients backing off at once don't retry in
+	// lockstep.
+	Jitter float64
+}
 
-	b, err := json.Marshal(resp)
-	if err != nil {
-		msg := fmt.Sprintf("error encoding response %#v to JSON: %v", resp, err)
-		handleError(w, r, http.StatusInternalServerError, msg)
-		return
+func jitter(d time.Duration, frac float64) time.Duration {
+	if frac <= 0 || d <= 0 {
+		return d
 	}
+	delta := frac * float64(d)
+	min := float64(d) - delta/2
+	return time.Duration(min + rand.Float64()*delta)
+}
 
-	_, err = w.Write(b)
-	if err != nil {
-		log.Printf("error writing response: %v", err)
+// deadlineGuard enforces independent write and read deadlines around a
+// single round trip, the same way a net.Conn wrapper splits SetReadDeadline
+// from SetWriteDeadline: each direction gets its own timer, and whichever
+// fires first closes cancelCh. Callers select on cancelCh alongside the
+// request's context.
+type deadlineGuard struct {
+	cancelCh chan struct{}
+	fireOnce sync.Once
+	writeT   *time.Timer
+	readT    *time.Timer
+}
+
+func newDeadlineGuard(writeTimeout time.Duration) *deadlineGuard {
+	g := &deadlineGuard{cancelCh: make(chan struct{})}
+	if writeTimeout > 0 {
+		g.writeT = time.AfterFunc(writeTimeout, g.fire)
 	}
+	return g
 }
 
-type Client struct {
-	Addr string
+// fire closes cancelCh. Both the write and read timers call it, and they run
+// on their own goroutines, so the close itself is guarded by a sync.Once
+// rather than a plain bool to avoid a racy double-close.
+func (g *deadlineGuard) fire() {
+	g.fireOnce.Do(func() {
+		close(g.cancelCh)
+	})
 }
 
-func (c Client) Sim(x, y Expr) (float32, error) {
-	req := SimQuery{A: x, B: y}
+// armRead stops the write timer and starts the read timer, marking the
+// transition from the write phase of the round trip to the read phase.
+func (g *deadlineGuard) armRead(readTimeout time.Duration) {
+	if g.writeT != nil {
+		g.writeT.Stop()
+	}
+	if readTimeout > 0 {
+		g.readT = time.AfterFunc(readTimeout, g.fire)
+	}
+}
 
-	b, err := json.Marshal(req)
-	if err != nil {
-		return 0.0, err
+func (g *deadlineGuard) stop() {
+	if g.writeT != nil {
+		g.writeT.Stop()
+	}
+	if g.readT != nil {
+		g.readT.Stop()
 	}
+}
 
-	r, err := http.NewRequest("GET", "http://"+c.Addr+"/sim", bytes.NewReader(b))
-	if err != nil {
-		return 0.0, err
+// do performs a GET round trip to path with the given JSON body, applying
+// c.Timeout, the split read/write deadlines, and c.Retry, and returns the
+// raw response body. GET requests carrying a body are idempotent here, so
+// every attempt is eligible for retry.
+// do performs the round trip described by doOnce, retrying per c.Retry, and
+// decodes the response into out.
+func (c Client) do(ctx context.Context, path string, body []byte, out interface{}) error {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	attempts := c.Retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := c.Retry.InitialBackoff
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			d := jitter(backoff, c.Retry.Jitter)
+			if c.Retry.MaxBackoff > 0 && d > c.Retry.MaxBackoff {
+				d = c.Retry.MaxBackoff
+			}
+			select {
+			case <-time.After(d):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff *= 2
+		}
+
+		retryable, err := c.doOnce(ctx, path, body, out)
+		if err == nil {
+			return nil
+		}
+		if !retryable {
+			return err
+		}
+		lastErr = err
 	}
+	return lastErr
+}
 
-	resp, err := http.DefaultClient.Do(r)
+// doOnce performs a single attempt of the round trip described by do,
+// decoding the response into out with the codec matching its Content-Type
+// (falling back to JSON), and reporting whether a failure is eligible for
+// retry (a connection error or an idempotent 5xx response).
+func (c Client) doOnce(ctx context.Context, path string, body []byte, out interface{}) (bool, error) {
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	guard := newDeadlineGuard(c.WriteTimeout)
+	defer guard.stop()
+
+	// Canceling reqCtx when the guard fires tears down the in-flight
+	// transport round trip itself, rather than just unblocking the select
+	// below while http.Client keeps the request (and its connection)
+	// running in the background.
+	go func() {
+		select {
+		case <-guard.cancelCh:
+			cancel()
+		case <-reqCtx.Done():
+		}
+	}()
+
+	r, err := http.NewRequestWithContext(reqCtx, "GET", "http://"+c.Addr+path, bytes.NewReader(body))
 	if err != nil {
-		return 0.0, err
+		return false, err
+	}
+	// Ask for the compact binary encoding by default; MostSimilar responses
+	// carrying thousands of Matches cost far less to marshal and transfer
+	// this way than as JSON.
+	r.Header.Set("Accept", protobufCodec{}.ContentType())
+
+	type doResult struct {
+		resp *http.Response
+		err  error
+	}
+	doneCh := make(chan doResult, 1)
+	go func() {
+		resp, err := c.httpClient().Do(r)
+		doneCh <- doResult{resp, err}
+	}()
+
+	guard.armRead(c.ReadTimeout)
+
+	var resp *http.Response
+	select {
+	case res := <-doneCh:
+		if res.err != nil {
+			return true, res.err
+		}
+		resp = res.resp
+	case <-guard.cancelCh:
+		return true, fmt.Errorf("word2vec: request to %v timed out", path)
+	case <-ctx.Done():
+		return false, ctx.Err()
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return 0.0, fmt.Errorf("non-%v status code: %v", http.StatusOK, resp.Status)
+		err := fmt.Errorf("non-%v status code: %v", http.StatusOK, resp.Status)
+		return resp.StatusCode >= 500, err
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	respBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return 0.0, fmt.Errorf("error reading response: %v", err)
+		return false, fmt.Errorf("error reading response: %v", err)
 	}
 
-	var data SimResponse
-	err = json.Unmarshal(body, &data)
+	ct := strings.TrimSpace(strings.SplitN(resp.Header.Get("Content-Type"), ";", 2)[0])
+	codec := codecByContentType(nil, ct)
+	if codec == nil {
+		codec = jsonCodec{}
+	}
+	if err := codec.Unmarshal(respBody, out); err != nil {
+		return false, fmt.Errorf("error unmarshalling result: %v", err)
+	}
+	return false, nil
+}
+
+func (c Client) Sim(ctx context.Context, x, y Expr) (float32, error) {
+	req := SimQuery{A: x, B: y}
+
+	b, err := json.Marshal(req)
 	if err != nil {
-		return 0.0, fmt.Errorf("error unmarshalling result: %v", err)
+		return 0.0, err
 	}
 
+	var data SimResponse
+	if err := c.do(ctx, "/sim", b, &data); err != nil {
+		return 0.0, err
+	}
 	return data.Value, nil
 }
 
-func (c Client) MultiSim(pairs [][2]Expr) ([]float32, error) {
+func (c Client) MultiSim(ctx context.Context, pairs [][2]Expr) ([]float32, error) {
 	req := MultiSimQuery{
 		Queries: make([]SimQuery, len(pairs)),
 	}
-	for _, pair := range pairs {
-		req.Queries = append(req.Queries, SimQuery{
+	for i, pair := range pairs {
+		req.Queries[i] = SimQuery{
 			A: pair[0],
 			B: pair[1],
-		})
+		}
 	}
 
 	b, err := json.Marshal(req)
@@ -264,35 +535,31 @@ func (c Client) MultiSim(pairs [][2]Expr) ([]float32, error) {
 		return nil, err
 	}
 
-	r, err := http.NewRequest("GET", "http://"+c.Addr+"/sim-multi", bytes.NewReader(b))
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := http.DefaultClient.Do(r)
-	if err != nil {
+	var data MultiSimResponse
+	if err := c.do(ctx, "/sim-multi", b, &data); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("non-%v status code: %v", http.StatusOK, resp.Status)
+	result := make([]float32, len(data.Values))
+	for i, v := range data.Values {
+		result[i] = v.Value
 	}
+	return result, nil
+}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response: %v", err)
-	}
+// MostSim evaluates expr and returns the n most similar matches, mirroring
+// ModelServer's /most-sim endpoint.
+func (c Client) MostSim(ctx context.Context, expr Expr, n int) ([]Match, error) {
+	req := MostSimQuery{Expr: expr, N: n}
 
-	var data MultiSimResponse
-	err = json.Unmarshal(body, &data)
+	b, err := json.Marshal(req)
 	if err != nil {
-		return nil, fmt.Errorf("error unmarshalling result: %v", err)
+		return nil, err
 	}
 
-	result := make([]float32, len(data.Values))
-	for i, v := range data.Values {
-		result[i] = v.Value
+	var data MostSimResponse
+	if err := c.do(ctx, "/most-sim", b, &data); err != nil {
+		return nil, err
 	}
-	return result, nil
+	return data.Matches, nil
 }
@@ -0,0 +1,181 @@
+package word2vec
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestServerObservesContextCancellation exercises the same r.Context()-based
+// cancellation ModelServer's handlers wait on (see HandleSimQuery et al.).
+// It's written against a plain http.HandlerFunc rather than ModelServer
+// itself because this tree has no concrete Model to construct one with.
+func TestServerObservesContextCancellation(t *testing.T) {
+	serverSawCancel := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			close(serverSawCancel)
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := http.DefaultClient.Do(req); err == nil {
+		t.Fatal("expected the request to fail once its context was canceled")
+	}
+
+	select {
+	case <-serverSawCancel:
+	case <-time.After(time.Second):
+		t.Fatal("server never observed the client's cancellation")
+	}
+}
+
+// TestClientSimRespectsContextCancellation checks that Client.Sim itself
+// gives up as soon as its context is done, rather than waiting on the
+// server.
+func TestClientSimRespectsContextCancellation(t *testing.T) {
+	serverSawCancel := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		close(serverSawCancel)
+	}))
+	defer srv.Close()
+
+	c := Client{Addr: strings.TrimPrefix(srv.URL, "http://")}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := c.Sim(ctx, Expr{"a": 1}, Expr{"b": 1}); err == nil {
+		t.Fatal("expected Sim to fail once the context deadline passed")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Sim took %v to return after cancellation, want well under 1s", elapsed)
+	}
+
+	select {
+	case <-serverSawCancel:
+	case <-time.After(time.Second):
+		t.Fatal("server handler never observed the client's cancellation")
+	}
+}
+
+// TestClientMultiSimSendsExactlyLenPairsQueries guards against the
+// make+append double-length bug in MultiSim, where the request ended up
+// carrying 2*len(pairs) queries, half of them zero-value.
+func TestClientMultiSimSendsExactlyLenPairsQueries(t *testing.T) {
+	var gotQueries int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading request: %v", err)
+		}
+
+		var q MultiSimQuery
+		if err := json.Unmarshal(body, &q); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		gotQueries = len(q.Queries)
+		for _, sq := range q.Queries {
+			if len(sq.A) == 0 || len(sq.B) == 0 {
+				t.Errorf("got a query with an empty Expr: %+v", sq)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(MultiSimResponse{Values: make([]SimResponse, len(q.Queries))})
+	}))
+	defer srv.Close()
+
+	c := Client{Addr: strings.TrimPrefix(srv.URL, "http://")}
+	pairs := [][2]Expr{
+		{Expr{"a": 1}, Expr{"b": 1}},
+		{Expr{"c": 1}, Expr{"d": 1}},
+	}
+
+	if _, err := c.MultiSim(context.Background(), pairs); err != nil {
+		t.Fatalf("MultiSim: %v", err)
+	}
+	if gotQueries != len(pairs) {
+		t.Fatalf("server received %d queries, want %d", gotQueries, len(pairs))
+	}
+}
+
+// TestClientRetriesOn5xx checks that Client.do retries an idempotent 5xx
+// response up to Retry.MaxAttempts before giving up.
+func TestClientRetriesOn5xx(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(SimResponse{Value: 0.5})
+	}))
+	defer srv.Close()
+
+	c := Client{
+		Addr: strings.TrimPrefix(srv.URL, "http://"),
+		Retry: RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: time.Millisecond,
+		},
+	}
+
+	v, err := c.Sim(context.Background(), Expr{"a": 1}, Expr{"b": 1})
+	if err != nil {
+		t.Fatalf("Sim: %v", err)
+	}
+	if v != 0.5 {
+		t.Fatalf("got %v, want 0.5", v)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Fatalf("server saw %d attempts, want 3", got)
+	}
+}
+
+// TestClientDoesNotRetryOn4xx checks that a non-retryable (client error)
+// status is not retried.
+func TestClientDoesNotRetryOn4xx(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	c := Client{
+		Addr: strings.TrimPrefix(srv.URL, "http://"),
+		Retry: RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: time.Millisecond,
+		},
+	}
+
+	if _, err := c.Sim(context.Background(), Expr{"a": 1}, Expr{"b": 1}); err == nil {
+		t.Fatal("expected Sim to return the 400 as an error")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Fatalf("server saw %d attempts, want 1 (no retry on 4xx)", got)
+	}
+}
@@ -0,0 +1,150 @@
+package word2vec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+)
+
+// HandleMostSimStream evaluates a MostSimQuery and streams each Match to the
+// client as a JSON line over a chunked response, instead of marshaling the
+// full MostSimResponse slice up front the way HandleMostSimQuery does. This
+// is a transport optimization, not a compute one: matches only start
+// flowing once the full MostSimilar scan has finished, so it does not
+// reduce time-to-first-match. What it buys a caller is (a) never paying to
+// buffer and marshal the whole slice as one JSON array before the first
+// byte goes out, and (b) the ability to stop reading partway through
+// (canceling the request context) and skip the transfer and decode cost of
+// the matches it doesn't need — at the cost of the server still completing
+// the scan for every caller, including ones who disconnect early, since
+// Eval takes no context (see evalWithCancel).
+//
+// DEFERRED: the original ask for this endpoint — shard the vocabulary,
+// maintain a partial top-K heap per shard, and flush a match to the client
+// as soon as its score is stable against every shard — is not implemented
+// here and should not be read as delivered by this handler. That needs
+// lower-level access to Model's vocabulary than its public
+// Evaluate/MostSimilar API exposes; tracking it as follow-up work once
+// Model grows that access.
+func (m *ModelServer) HandleMostSimStream(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	reqBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		msg := fmt.Sprintf("error reading request: %v", err)
+		handleError(w, r, http.StatusInternalServerError, msg)
+		return
+	}
+
+	var q MostSimQuery
+	if err := m.requestCodec(r).Unmarshal(reqBody, &q); err != nil {
+		msg := fmt.Sprintf("error decoding query: %v", err)
+		handleError(w, r, http.StatusInternalServerError, msg)
+		return
+	}
+
+	ctx := r.Context()
+
+	// See evalWithCancel: Eval takes no context, so a canceled ctx here only
+	// abandons this response; it does not stop the scan, which keeps
+	// running on its goroutine to completion.
+	resp, err := evalWithCancel(ctx, m.Model, q, MostSimQuery.Eval)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			msg := fmt.Sprintf("request canceled: %v", err)
+			handleError(w, r, http.StatusGatewayTimeout, msg)
+			return
+		}
+		msg := fmt.Sprintf("error evaluating query: %v", err)
+		handleError(w, r, http.StatusBadRequest, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Transfer-Encoding", "chunked")
+
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	for _, match := range resp.Matches {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if err := enc.Encode(match); err != nil {
+			log.Printf("error writing match: %v", err)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// MostSimStream calls HandleMostSimStream and returns the matches as they
+// arrive on matchCh, plus errCh which carries at most one error. Both
+// channels are closed once the stream ends; canceling ctx stops reading and
+// closes matchCh without waiting for the rest of the response. Note this
+// only saves the client the transfer/decode cost of the remaining matches —
+// see HandleMostSimStream for why it doesn't shorten the server's scan.
+func (c Client) MostSimStream(ctx context.Context, expr Expr, n int) (<-chan Match, <-chan error) {
+	matchCh := make(chan Match)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(matchCh)
+		defer close(errCh)
+
+		req := MostSimQuery{Expr: expr, N: n}
+		b, err := json.Marshal(req)
+		if err != nil {
+			errCh <- err
+			return
+		}
+
+		r, err := http.NewRequestWithContext(ctx, "GET", "http://"+c.Addr+"/most-sim-stream", bytes.NewReader(b))
+		if err != nil {
+			errCh <- err
+			return
+		}
+		r.Header.Set("Accept", "application/x-ndjson")
+
+		resp, err := c.httpClient().Do(r)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			errCh <- fmt.Errorf("non-%v status code: %v", http.StatusOK, resp.Status)
+			return
+		}
+
+		dec := json.NewDecoder(resp.Body)
+		for {
+			var match Match
+			if err := dec.Decode(&match); err != nil {
+				if err != io.EOF {
+					errCh <- err
+				}
+				return
+			}
+
+			select {
+			case matchCh <- match:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return matchCh, errCh
+}